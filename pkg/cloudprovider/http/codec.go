@@ -0,0 +1,85 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+)
+
+// Codec marshals and unmarshals the request/response bodies exchanged with
+// the instances and scheduler-extension endpoints, and reports the MIME
+// type to use for Content-Type/Accept.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	ContentType() string
+}
+
+// codecs holds the Codec implementations selectable via the codec setting
+// in Config.Global.
+var codecs = map[string]Codec{
+	"json": jsonCodec{},
+	"gob":  gobCodec{},
+}
+
+// codecFor looks up the configured codec, defaulting to JSON when name is
+// empty, and erroring on an unknown name so a typo in the config is caught
+// at startup rather than silently falling back.
+func codecFor(name string) (Codec, error) {
+	if name == "" {
+		return jsonCodec{}, nil
+	}
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown codec %q, must be one of json, gob", name)
+	}
+	return codec, nil
+}
+
+// jsonCodec is the default codec, used when codec is unset.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) ContentType() string                        { return "application/json" }
+
+// gobCodec avoids the JSON tag/reflection overhead at the cost of only
+// being usable between Go peers running compatible versions of this
+// package's types.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func (gobCodec) ContentType() string { return "application/x-gob" }
+
+// A protobuf codec was considered here, but every type actually passed to
+// Codec.Marshal/Unmarshal in this package (FilterArgs, PriorityArgs,
+// BindArgs, PreemptionArgs, ReserveArgs, PermitArgs, *api.Pod) is a
+// hand-written struct with only json tags, not a generated proto.Message.
+// Revisit once those gain real protobuf counterparts; until then "proto" is
+// deliberately absent from codecs so a config typo fails at startup instead
+// of every call failing at runtime.
@@ -15,20 +15,25 @@ package http
 
 import (
 	"bytes"
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
 	"github.com/GoogleCloudPlatform/kubernetes/pkg/cloudprovider"
 
 	"code.google.com/p/gcfg"
+	"github.com/golang/glog"
 )
 
 // httpCloud represents the parsed configuration for the http cloud provider
@@ -40,6 +45,24 @@ type httpCloud struct {
 	clustersSupported           bool
 	schedulerExtensionSupported bool
 	schedulerExtensionURL       string
+	nodeCacheCapable            bool
+	bearerToken                 string
+	client                      *http.Client
+	watchClient                 *http.Client
+
+	retries      int
+	retryBackoff time.Duration
+
+	instancesIgnorable          bool
+	schedulerExtensionIgnorable bool
+	instancesBreaker            *circuitBreaker
+	schedulerExtensionBreaker   *circuitBreaker
+
+	codec Codec
+
+	watch          bool
+	resyncInterval time.Duration
+	cache          *instanceCache
 }
 
 // Config represents the supplied configuration for the http cloud provider
@@ -52,6 +75,44 @@ type Config struct {
 		ClustersSupported           bool   `gcfg:"clusters"`
 		SchedulerExtensionSupported bool   `gcfg:"scheduler-extension"`
 		SchedulerExtensionURL       string `gcfg:"scheduler-extension-url"`
+
+		// NodeCacheCapable makes Filter/Prioritize send the extender only the
+		// pod plus the candidate node names instead of the full api.NodeList,
+		// so the extender keeps its own node cache. This is the
+		// "managed nodes" mode used by production scheduler extenders.
+		NodeCacheCapable bool `gcfg:"node-cache-capable"`
+
+		// HttpRetries and HttpRetryBackoff configure retrying of failed
+		// instances/scheduler-extension calls with exponential backoff and
+		// jitter. InstancesIgnorable and SchedulerExtensionIgnorable, when
+		// set, tell the provider to degrade gracefully instead of failing
+		// the scheduling cycle once retries are exhausted.
+		HttpRetries                 int    `gcfg:"http-retries"`
+		HttpRetryBackoff            string `gcfg:"http-retry-backoff"`
+		InstancesIgnorable          bool   `gcfg:"instances-ignorable"`
+		SchedulerExtensionIgnorable bool   `gcfg:"scheduler-extension-ignorable"`
+
+		// CAFile, ClientCertFile and ClientKeyFile configure mutual TLS between
+		// this provider and the instances/scheduler-extension endpoints, mirroring
+		// the certificate options accepted by the kubeconfig and webhook admission
+		// plugins.
+		CAFile             string `gcfg:"ca-file"`
+		ClientCertFile     string `gcfg:"client-cert-file"`
+		ClientKeyFile      string `gcfg:"client-key-file"`
+		BearerTokenFile    string `gcfg:"bearer-token-file"`
+		InsecureSkipVerify bool   `gcfg:"insecure-skip-verify"`
+
+		// Codec selects the wire format used to talk to the instances and
+		// scheduler-extension endpoints: json (default) or gob.
+		Codec string `gcfg:"codec"`
+
+		// Watch, when true, maintains an in-memory instance cache fed by a
+		// long-lived streaming GET on the instances endpoint instead of
+		// issuing a synchronous GET for every NodeAddresses/List/
+		// GetNodeResources call. ResyncInterval bounds how long the cache is
+		// trusted between full re-syncs of the watch connection.
+		Watch          bool   `gcfg:"watch"`
+		ResyncInterval string `gcfg:"resync-interval"`
 	}
 }
 
@@ -65,22 +126,144 @@ const (
 	SchedulerExtensionPrioritize = "prioritize"
 	SchedulerExtensionBind       = "bind"
 	SchedulerExtensionUnbind     = "unbind"
+	SchedulerExtensionPreempt    = "preempt"
+	SchedulerExtensionReserve    = "reserve"
+	SchedulerExtensionPermit     = "permit"
+
+	// DefaultRetryBackoff is used when http-retry-backoff is unset; a value
+	// that fails to parse is rejected by newHTTPCloud.
+	DefaultRetryBackoff = 100 * time.Millisecond
+
+	// CircuitBreakerThreshold is the number of consecutive failures on an
+	// endpoint after which the circuit opens and further calls are failed
+	// fast until CircuitBreakerCooldown elapses.
+	CircuitBreakerThreshold = 5
+	CircuitBreakerCooldown  = 30 * time.Second
+
+	// InstanceWatchPath is InstancesPath with the watch query string that
+	// switches the endpoint from a plain list to a streaming event feed.
+	InstanceWatchPath = InstancesPath + "?watch=true"
+
+	// DefaultResyncInterval is how often the watch connection is torn down
+	// and re-established to pick up any events silently missed on the wire.
+	DefaultResyncInterval = 30 * time.Minute
+
+	// WatchReconnectBackoff is the base backoff between reconnect attempts
+	// after the watch connection drops.
+	WatchReconnectBackoff = time.Second
 )
 
-// FilterArgs represents the arguments needed for filtering nodes for a pod
+// FilterArgs represents the arguments needed for filtering nodes for a pod.
+// When the extender is node-cache-capable only NodeNames is populated;
+// otherwise only Nodes is.
 type FilterArgs struct {
-	Pod   api.Pod      `json:"pod"`
-	Nodes api.NodeList `json:"nodes"`
+	Pod       api.Pod       `json:"pod"`
+	Nodes     *api.NodeList `json:"nodes,omitempty"`
+	NodeNames *[]string     `json:"nodeNames,omitempty"`
 }
 
 type PriorityArgs FilterArgs
 
+// FilterResult represents the extender's response to Filter/Prioritize when
+// node-cache-capable is set: the surviving node names plus the reason each
+// filtered-out node was rejected.
+type FilterResult struct {
+	NodeNames   *[]string         `json:"nodeNames,omitempty"`
+	FailedNodes map[string]string `json:"failedNodes,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
 // BindArgs represents the arguments needed to bind a pod to a host
 type BindArgs struct {
 	Pod  api.Pod `json:"pod"`
 	Host string  `json:"host"`
 }
 
+// Victims is the set of pods on a node that would need to be preempted to
+// make room for a pending pod, along with how many of them would violate a
+// PodDisruptionBudget if preempted.
+type Victims struct {
+	Pods             []api.Pod `json:"pods"`
+	NumPDBViolations int       `json:"numPDBViolations"`
+}
+
+// PreemptionArgs represents the arguments needed to ask the extender which
+// of the candidate victims on each node it is willing to have preempted for
+// the pod. NodeNames is an optional node-name filter; Preempt does not set
+// it, so it is omitted from the request even when node-cache-capable is on.
+type PreemptionArgs struct {
+	Pod               api.Pod             `json:"pod"`
+	NodeNameToVictims map[string]*Victims `json:"nodeNameToVictims"`
+	NodeNames         *[]string           `json:"nodeNames,omitempty"`
+}
+
+// PreemptionResult represents the extender's response: the subset of the
+// proposed victims per node that it agrees can be preempted.
+type PreemptionResult struct {
+	NodeNameToVictims map[string]*Victims `json:"nodeNameToVictims"`
+}
+
+// ReserveArgs represents the arguments needed to reserve resources for a pod
+// on a host ahead of Bind, so a later Permit/Bind failure can be rolled back.
+type ReserveArgs struct {
+	Pod  api.Pod `json:"pod"`
+	Host string  `json:"host"`
+}
+
+// PermitArgs represents the arguments needed to ask the extender whether a
+// reserved pod may proceed to Bind.
+type PermitArgs ReserveArgs
+
+// ErrExtenderUnavailable is returned by Bind when scheduler-extension-ignorable
+// is set and the extender could not be reached after retries, so the caller
+// should fall back to the default binder instead of failing the pod.
+var ErrExtenderUnavailable = errors.New("http cloudprovider: scheduler extension unavailable, falling back to default binder")
+
+// circuitBreaker fails calls fast once an endpoint has accumulated threshold
+// consecutive failures, until cooldown has elapsed since the breaker opened.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow reports whether a call should be attempted. Once the cooldown has
+// elapsed since the breaker opened it allows a single probe call through and
+// restarts the cooldown, so later calls are refused until a success closes it.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Since(b.openedAt) >= b.cooldown {
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+func (b *circuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures == b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
 func init() {
 	cloudprovider.RegisterCloudProvider("http", func(config io.Reader) (cloudprovider.Interface, error) { return newHTTPCloud(config) })
 }
@@ -111,7 +294,44 @@ func newHTTPCloud(config io.Reader) (*httpCloud, error) {
 		// Handle Trailing slashes
 		schedulerExtensionURL = strings.TrimRight(schedulerExtensionURL, "/")
 
-		return &httpCloud{
+		tlsConfig, err := newTLSConfig(cfg.Global.CAFile, cfg.Global.ClientCertFile, cfg.Global.ClientKeyFile, cfg.Global.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+
+		bearerToken, err := bearerTokenFromFile(cfg.Global.BearerTokenFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.Global.HttpRetries < 0 {
+			return nil, fmt.Errorf("http-retries must be >= 0, got %d", cfg.Global.HttpRetries)
+		}
+
+		retryBackoff := DefaultRetryBackoff
+		if cfg.Global.HttpRetryBackoff != "" {
+			retryBackoff, err = time.ParseDuration(cfg.Global.HttpRetryBackoff)
+			if err != nil {
+				return nil, fmt.Errorf("Can't parse the http-retry-backoff provided: %v", err)
+			}
+		}
+
+		codec, err := codecFor(cfg.Global.Codec)
+		if err != nil {
+			return nil, err
+		}
+
+		resyncInterval := DefaultResyncInterval
+		if cfg.Global.ResyncInterval != "" {
+			resyncInterval, err = time.ParseDuration(cfg.Global.ResyncInterval)
+			if err != nil {
+				return nil, fmt.Errorf("Can't parse the resync-interval provided: %v", err)
+			}
+		}
+
+		transport := &http.Transport{TLSClientConfig: tlsConfig}
+
+		h := &httpCloud{
 			instancesURL:                instancesURL,
 			instancesSupported:          cfg.Global.InstancesSupported,
 			tcpLoadBalancerSupported:    cfg.Global.TcpLoadBalancerSupported,
@@ -119,11 +339,81 @@ func newHTTPCloud(config io.Reader) (*httpCloud, error) {
 			clustersSupported:           cfg.Global.ClustersSupported,
 			schedulerExtensionURL:       schedulerExtensionURL,
 			schedulerExtensionSupported: cfg.Global.SchedulerExtensionSupported,
-		}, nil
+			nodeCacheCapable:            cfg.Global.NodeCacheCapable,
+			bearerToken:                 bearerToken,
+			client: &http.Client{
+				Transport: transport,
+				Timeout:   HttpProviderTimeout,
+			},
+			// watchClient has no overall timeout: http.Client.Timeout bounds
+			// the whole request including reading the (intentionally
+			// long-lived) response body, which would otherwise sever the
+			// watch connection every HttpProviderTimeout.
+			watchClient:                 &http.Client{Transport: transport},
+			retries:                     cfg.Global.HttpRetries,
+			retryBackoff:                retryBackoff,
+			instancesIgnorable:          cfg.Global.InstancesIgnorable,
+			schedulerExtensionIgnorable: cfg.Global.SchedulerExtensionIgnorable,
+			instancesBreaker:            newCircuitBreaker(CircuitBreakerThreshold, CircuitBreakerCooldown),
+			schedulerExtensionBreaker:   newCircuitBreaker(CircuitBreakerThreshold, CircuitBreakerCooldown),
+			codec:                       codec,
+			watch:                       cfg.Global.Watch,
+			resyncInterval:              resyncInterval,
+		}
+
+		if h.watch {
+			h.cache = newInstanceCache()
+			go h.watchInstances()
+		}
+
+		return h, nil
 	}
 	return nil, fmt.Errorf("Config file is empty or is not provided")
 }
 
+// newTLSConfig builds the *tls.Config used to talk to the instances and
+// scheduler-extension endpoints from the ca-file/client-cert-file/
+// client-key-file/insecure-skip-verify settings in Config.Global.
+func newTLSConfig(caFile, clientCertFile, clientKeyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if caFile != "" {
+		caCert, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read ca-file %s: %v", caFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No certificates could be parsed from ca-file %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if clientCertFile != "" || clientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't load client-cert-file/client-key-file: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// bearerTokenFromFile reads the bearer token used to authenticate to the
+// instances and scheduler-extension endpoints. Returns the empty string if
+// no file is configured.
+func bearerTokenFromFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	token, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't read bearer-token-file %s: %v", path, err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
 // Returns an implementation of Instances for HTTP cloud.
 func (h *httpCloud) Instances() (cloudprovider.Instances, bool) {
 	if h.instancesSupported {
@@ -159,6 +449,12 @@ func (h *httpCloud) SchedulerExtension() (cloudprovider.SchedulerExtension, bool
 
 // Returns the addresses of a particular instance.
 func (h *httpCloud) NodeAddresses(instance string) ([]api.NodeAddress, error) {
+	if h.watch {
+		if entry, ok := h.cache.get(instance); ok {
+			return entry.Addresses, nil
+		}
+	}
+
 	var resp []api.NodeAddress
 	if err := h.get(h.instancesURL+path.Join(InstancesPath, instance, InstanceAddressesPath), &resp); err != nil {
 		return nil, err
@@ -174,6 +470,12 @@ func (h *httpCloud) ExternalID(instance string) (string, error) {
 
 // Enumerates the set of minions instances known by the cloud provider.
 func (h *httpCloud) List(filter string) ([]string, error) {
+	if h.watch {
+		if names, ok := h.cache.list(filter); ok {
+			return names, nil
+		}
+	}
+
 	var resp []string
 	if err := h.get(h.instancesURL+path.Join(InstancesPath, filter), &resp); err != nil {
 		return nil, err
@@ -184,6 +486,12 @@ func (h *httpCloud) List(filter string) ([]string, error) {
 
 // Gets the resources for a particular node.
 func (h *httpCloud) GetNodeResources(instance string) (*api.NodeResources, error) {
+	if h.watch {
+		if entry, ok := h.cache.get(instance); ok {
+			return entry.Resources, nil
+		}
+	}
+
 	var resp api.NodeResources
 	if err := h.get(h.instancesURL+path.Join(InstancesPath, instance, InstanceResourcesPath), &resp); err != nil {
 		return nil, err
@@ -194,22 +502,74 @@ func (h *httpCloud) GetNodeResources(instance string) (*api.NodeResources, error
 
 // Filter based on provider implemented predicate functions.
 func (h *httpCloud) Filter(pod *api.Pod, nodes *api.NodeList) (*api.NodeList, error) {
-	var resp api.NodeList
+	args := &FilterArgs{Pod: *pod}
 
-	args := &FilterArgs{
-		Pod:   *pod,
-		Nodes: *nodes,
+	if h.nodeCacheCapable {
+		nodeNames := nodeNamesOf(nodes)
+		args.NodeNames = &nodeNames
+	} else {
+		args.Nodes = nodes
 	}
 
-	if out, err := json.Marshal(args); err != nil {
+	out, err := h.codec.Marshal(args)
+	if err != nil {
 		return nil, err
-	} else {
-		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionFilter), bytes.NewReader(out), &resp); err != nil {
+	}
+
+	if h.nodeCacheCapable {
+		var resp FilterResult
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionFilter), out, &resp); err != nil {
+			if h.schedulerExtensionIgnorable {
+				glog.Errorf("Ignoring Filter failure from scheduler extension, passing all nodes through: %v", err)
+				return nodes, nil
+			}
 			return nil, err
-		} else {
-			return &resp, nil
+		}
+		if resp.Error != "" {
+			return nil, fmt.Errorf("Extender Filter failed: %s", resp.Error)
+		}
+		if len(resp.FailedNodes) > 0 {
+			glog.V(4).Infof("Extender Filter rejected nodes for pod %s: %v", pod.Name, resp.FailedNodes)
+		}
+		return filterNodesByName(nodes, resp.NodeNames), nil
+	}
+
+	var resp api.NodeList
+	if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionFilter), out, &resp); err != nil {
+		if h.schedulerExtensionIgnorable {
+			glog.Errorf("Ignoring Filter failure from scheduler extension, passing all nodes through: %v", err)
+			return nodes, nil
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// nodeNamesOf returns the names of the nodes in the list, in order.
+func nodeNamesOf(nodes *api.NodeList) []string {
+	names := make([]string, len(nodes.Items))
+	for i, node := range nodes.Items {
+		names[i] = node.Name
+	}
+	return names
+}
+
+// filterNodesByName returns the subset of nodes whose name appears in names.
+func filterNodesByName(nodes *api.NodeList, names *[]string) *api.NodeList {
+	resp := &api.NodeList{}
+	if names == nil {
+		return resp
+	}
+	wanted := make(map[string]bool, len(*names))
+	for _, name := range *names {
+		wanted[name] = true
+	}
+	for _, node := range nodes.Items {
+		if wanted[node.Name] {
+			resp.Items = append(resp.Items, node)
 		}
 	}
+	return resp
 }
 
 // Prioritize based on provider implemented priority functions. Weight*priority
@@ -219,15 +579,22 @@ func (h *httpCloud) Filter(pod *api.Pod, nodes *api.NodeList) (*api.NodeList, er
 func (h *httpCloud) Prioritize(pod *api.Pod, nodes *api.NodeList) (*api.HostPriorityList, error) {
 	var resp api.HostPriorityList
 
-	args := &PriorityArgs{
-		Pod:   *pod,
-		Nodes: *nodes,
+	args := &PriorityArgs{Pod: *pod}
+	if h.nodeCacheCapable {
+		nodeNames := nodeNamesOf(nodes)
+		args.NodeNames = &nodeNames
+	} else {
+		args.Nodes = nodes
 	}
 
-	if out, err := json.Marshal(args); err != nil {
+	if out, err := h.codec.Marshal(args); err != nil {
 		return nil, err
 	} else {
-		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionPrioritize), bytes.NewReader(out), &resp); err != nil {
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionPrioritize), out, &resp); err != nil {
+			if h.schedulerExtensionIgnorable {
+				glog.Errorf("Ignoring Prioritize failure from scheduler extension, no scores added: %v", err)
+				return &api.HostPriorityList{}, nil
+			}
 			return nil, err
 		} else {
 			return &resp, nil
@@ -246,10 +613,14 @@ func (h *httpCloud) Bind(pod *api.Pod, host string) (map[string]string, error) {
 		Host: host,
 	}
 
-	if out, err := json.Marshal(args); err != nil {
+	if out, err := h.codec.Marshal(args); err != nil {
 		return nil, err
 	} else {
-		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionBind), bytes.NewReader(out), &resp); err != nil {
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionBind), out, &resp); err != nil {
+			if h.schedulerExtensionIgnorable {
+				glog.Errorf("Ignoring Bind failure from scheduler extension, falling back to the default binder: %v", err)
+				return nil, ErrExtenderUnavailable
+			}
 			return nil, err
 		} else {
 			return resp, nil
@@ -261,10 +632,10 @@ func (h *httpCloud) Bind(pod *api.Pod, host string) (map[string]string, error) {
 // To be called by scheduler when Bind with apiserver fails or by apiserver in
 // pod deletion path.
 func (h *httpCloud) Unbind(pod *api.Pod) error {
-	if out, err := json.Marshal(pod); err != nil {
+	if out, err := h.codec.Marshal(pod); err != nil {
 		return err
 	} else {
-		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionUnbind), bytes.NewReader(out), nil); err != nil {
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionUnbind), out, nil); err != nil {
 			return err
 		} else {
 			return nil
@@ -272,41 +643,158 @@ func (h *httpCloud) Unbind(pod *api.Pod) error {
 	}
 }
 
-// Helper function to send a http request.
-func (h *httpCloud) sendHTTPRequest(requestType string, url string, requestBody io.Reader) ([]byte, error) {
-	req, err := http.NewRequest(requestType, url, requestBody)
-	if err != nil {
+// Preempt asks the provider which of the candidate victims per node it is
+// willing to see preempted to make room for the pod, so it can participate
+// in the scheduler's preemption decision rather than only filter/prioritize.
+func (h *httpCloud) Preempt(pod *api.Pod, nodeNameToVictims map[string]*Victims) (map[string]*Victims, error) {
+	var resp PreemptionResult
+
+	args := &PreemptionArgs{
+		Pod:               *pod,
+		NodeNameToVictims: nodeNameToVictims,
+	}
+
+	if out, err := h.codec.Marshal(args); err != nil {
 		return nil, err
+	} else {
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionPreempt), out, &resp); err != nil {
+			if h.schedulerExtensionIgnorable {
+				glog.Errorf("Ignoring Preempt failure from scheduler extension, proposed victims unchanged: %v", err)
+				return nodeNameToVictims, nil
+			}
+			return nil, err
+		} else {
+			return resp.NodeNameToVictims, nil
+		}
 	}
+}
 
-	client := &http.Client{
-		Transport: http.DefaultTransport,
-		Timeout:   HttpProviderTimeout,
+// Reserve tells the provider to reserve the resources for the pod on host
+// ahead of Bind, so the reservation can be rolled back if a later phase
+// (Permit or Bind) fails.
+func (h *httpCloud) Reserve(pod *api.Pod, host string) error {
+	args := &ReserveArgs{
+		Pod:  *pod,
+		Host: host,
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if out, err := h.codec.Marshal(args); err != nil {
+		return err
+	} else {
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionReserve), out, nil); err != nil {
+			if h.schedulerExtensionIgnorable {
+				glog.Errorf("Ignoring Reserve failure from scheduler extension, no reservation recorded: %v", err)
+				return nil
+			}
+			return err
+		}
+		return nil
 	}
+}
 
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// Permit asks the provider whether a previously reserved pod may proceed to
+// Bind. A false result means the pod must wait or be rescheduled.
+func (h *httpCloud) Permit(pod *api.Pod, host string) (bool, error) {
+	var resp struct {
+		Allow bool `json:"allow"`
+	}
+
+	args := &PermitArgs{
+		Pod:  *pod,
+		Host: host,
+	}
+
+	if out, err := h.codec.Marshal(args); err != nil {
+		return false, err
 	} else {
+		if err := h.post(h.schedulerExtensionURL+path.Join(SchedulerExtensionPath, SchedulerExtensionPermit), out, &resp); err != nil {
+			if h.schedulerExtensionIgnorable {
+				glog.Errorf("Ignoring Permit failure from scheduler extension, allowing bind to proceed: %v", err)
+				return true, nil
+			}
+			return false, err
+		} else {
+			return resp.Allow, nil
+		}
+	}
+}
+
+// backoffWithJitter returns the delay before retry attempt n (1-based),
+// growing exponentially from base with up to 50% random jitter.
+func backoffWithJitter(base time.Duration, attempt int) time.Duration {
+	delay := base << uint(attempt-1)
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay + jitter
+}
+
+// Helper function to send a http request, retrying with exponential backoff
+// on failure and short-circuiting through breaker once it has tripped.
+func (h *httpCloud) sendHTTPRequest(requestType string, url string, requestBody []byte, breaker *circuitBreaker) ([]byte, error) {
+	if breaker != nil && !breaker.allow() {
+		return nil, fmt.Errorf("circuit breaker open for %s", url)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= h.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoffWithJitter(h.retryBackoff, attempt))
+		}
+
+		var bodyReader io.Reader
+		if requestBody != nil {
+			bodyReader = bytes.NewReader(requestBody)
+		}
+
+		req, err := http.NewRequest(requestType, url, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		if h.bearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+h.bearerToken)
+		}
+		if requestBody != nil {
+			req.Header.Set("Content-Type", h.codec.ContentType())
+		}
+		req.Header.Set("Accept", h.codec.ContentType())
+
+		resp, err := h.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if breaker != nil {
+			breaker.recordSuccess()
+		}
 		return body, nil
 	}
+
+	if breaker != nil {
+		breaker.recordFailure()
+	}
+	return nil, lastErr
 }
 
 // Sends a HTTP Get Request and Unmarshals the JSON Response.
 func (h *httpCloud) get(path string, resp interface{}) error {
 	requestType := "GET"
-	body, err := h.sendHTTPRequest(requestType, path, nil)
+	body, err := h.sendHTTPRequest(requestType, path, nil, h.instancesBreaker)
 	if err != nil {
+		if h.instancesIgnorable {
+			glog.Errorf("Ignoring instances GET failure for %s: %v", path, err)
+			return nil
+		}
 		return fmt.Errorf("HTTP request to cloudprovider failed: %v", err)
 	}
 	if body != nil {
-		if err := json.Unmarshal(body, resp); err != nil {
+		if err := h.codec.Unmarshal(body, resp); err != nil {
 			return fmt.Errorf("GET response Unmarshal for %s failed with error: %v\n", path, err)
 		}
 	}
@@ -314,14 +802,14 @@ func (h *httpCloud) get(path string, resp interface{}) error {
 }
 
 // Sends a HTTP Post Request and Unmarshals the JSON Response.
-func (h *httpCloud) post(path string, req io.Reader, resp interface{}) error {
+func (h *httpCloud) post(path string, req []byte, resp interface{}) error {
 	requestType := "POST"
-	body, err := h.sendHTTPRequest(requestType, path, req)
+	body, err := h.sendHTTPRequest(requestType, path, req, h.schedulerExtensionBreaker)
 	if err != nil {
 		return fmt.Errorf("HTTP request to cloudprovider failed: %v", err)
 	}
 	if body != nil && resp != nil {
-		if err := json.Unmarshal(body, resp); err != nil {
+		if err := h.codec.Unmarshal(body, resp); err != nil {
 			return fmt.Errorf("POST response Unmarshal for %s failed with error: %v\n", path, err)
 		}
 	}
@@ -0,0 +1,279 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+const minimalValidConfig = `
+[global]
+instances-url = http://127.0.0.1:9999
+scheduler-extension-url = http://127.0.0.1:9999
+`
+
+func TestNewHTTPCloudRejectsNegativeHttpRetries(t *testing.T) {
+	config := minimalValidConfig + "http-retries = -1\n"
+	if _, err := newHTTPCloud(strings.NewReader(config)); err == nil {
+		t.Fatal("expected a negative http-retries to be rejected at startup")
+	}
+}
+
+func TestNewHTTPCloudAcceptsNonNegativeHttpRetries(t *testing.T) {
+	config := minimalValidConfig + "http-retries = 0\n"
+	h, err := newHTTPCloud(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.retries != 0 {
+		t.Fatalf("h.retries = %d, want 0", h.retries)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	b := newCircuitBreaker(3, 10*time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("expected a fresh breaker to allow calls")
+	}
+
+	for i := 0; i < 3; i++ {
+		b.recordFailure()
+	}
+	if b.allow() {
+		t.Fatal("expected the breaker to be open after threshold consecutive failures")
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("expected the breaker to allow a single probe call once cooldown has elapsed")
+	}
+	if b.allow() {
+		t.Fatal("expected the breaker to stay open between probe calls until the probe succeeds or fails")
+	}
+
+	b.recordSuccess()
+	if !b.allow() {
+		t.Fatal("expected the breaker to be closed after a successful probe")
+	}
+}
+
+func TestCircuitBreakerRecordSuccessResetsFailures(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+	b.recordFailure()
+	b.recordSuccess()
+	b.recordFailure()
+	if !b.allow() {
+		t.Fatal("expected a success between failures to reset the failure count")
+	}
+}
+
+func TestBackoffWithJitter(t *testing.T) {
+	base := 100 * time.Millisecond
+	prevMax := time.Duration(0)
+	for attempt := 1; attempt <= 4; attempt++ {
+		want := base << uint(attempt-1)
+		for i := 0; i < 20; i++ {
+			got := backoffWithJitter(base, attempt)
+			if got < want || got > want+want/2 {
+				t.Fatalf("attempt %d: backoffWithJitter = %v, want in [%v, %v]", attempt, got, want, want+want/2)
+			}
+		}
+		if want <= prevMax {
+			t.Fatalf("attempt %d: base delay %v did not grow from previous attempt's max %v", attempt, want, prevMax)
+		}
+		prevMax = want + want/2
+	}
+}
+
+func TestNodeNamesOf(t *testing.T) {
+	nodes := &api.NodeList{Items: []api.Node{
+		{ObjectMeta: api.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: api.ObjectMeta{Name: "node-2"}},
+	}}
+	names := nodeNamesOf(nodes)
+	if len(names) != 2 || names[0] != "node-1" || names[1] != "node-2" {
+		t.Fatalf("nodeNamesOf = %v, want [node-1 node-2]", names)
+	}
+}
+
+func TestNodeNamesOfEmpty(t *testing.T) {
+	names := nodeNamesOf(&api.NodeList{})
+	if len(names) != 0 {
+		t.Fatalf("nodeNamesOf of an empty list = %v, want empty", names)
+	}
+}
+
+func TestFilterNodesByName(t *testing.T) {
+	nodes := &api.NodeList{Items: []api.Node{
+		{ObjectMeta: api.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: api.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: api.ObjectMeta{Name: "node-3"}},
+	}}
+
+	names := []string{"node-1", "node-3", "node-missing"}
+	got := filterNodesByName(nodes, &names)
+	if len(got.Items) != 2 || got.Items[0].Name != "node-1" || got.Items[1].Name != "node-3" {
+		t.Fatalf("filterNodesByName = %v, want [node-1 node-3]", got.Items)
+	}
+}
+
+func TestFilterNodesByNameNilNames(t *testing.T) {
+	nodes := &api.NodeList{Items: []api.Node{{ObjectMeta: api.ObjectMeta{Name: "node-1"}}}}
+	got := filterNodesByName(nodes, nil)
+	if len(got.Items) != 0 {
+		t.Fatalf("filterNodesByName with nil names = %v, want empty", got.Items)
+	}
+}
+
+func TestBearerTokenFromFile(t *testing.T) {
+	if token, err := bearerTokenFromFile(""); err != nil || token != "" {
+		t.Fatalf("bearerTokenFromFile(\"\") = (%q, %v), want (\"\", nil)", token, err)
+	}
+
+	dir, err := ioutil.TempDir("", "bearer-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := bearerTokenFromFile(tokenFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "s3cr3t" {
+		t.Fatalf("bearerTokenFromFile = %q, want %q (trailing whitespace trimmed)", token, "s3cr3t")
+	}
+
+	if _, err := bearerTokenFromFile(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected an error reading a bearer-token-file that does not exist")
+	}
+}
+
+func TestNewTLSConfigDefaults(t *testing.T) {
+	tlsConfig, err := newTLSConfig("", "", "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tlsConfig.InsecureSkipVerify {
+		t.Fatal("expected InsecureSkipVerify to be carried through")
+	}
+	if tlsConfig.RootCAs != nil || tlsConfig.Certificates != nil {
+		t.Fatal("expected no RootCAs/Certificates when no files are configured")
+	}
+}
+
+func TestNewTLSConfigMissingCAFile(t *testing.T) {
+	if _, err := newTLSConfig("/nonexistent/ca.pem", "", "", false); err == nil {
+		t.Fatal("expected an error reading a ca-file that does not exist")
+	}
+}
+
+func TestNewTLSConfigInvalidCAFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ca-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(caFile, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTLSConfig(caFile, "", "", false); err == nil {
+		t.Fatal("expected an error parsing a ca-file with no PEM certificates")
+	}
+}
+
+func TestNewTLSConfigMissingClientCert(t *testing.T) {
+	if _, err := newTLSConfig("", "/nonexistent/cert.pem", "/nonexistent/key.pem", false); err == nil {
+		t.Fatal("expected an error loading a client-cert-file/client-key-file pair that does not exist")
+	}
+}
+
+func TestNewTLSConfigWithCAAndClientCert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certPEM, keyPEM := generateSelfSignedCert(t)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	for path, data := range map[string][]byte{caFile: certPEM, certFile: certPEM, keyFile: keyPEM} {
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tlsConfig, err := newTLSConfig(caFile, certFile, keyFile, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tlsConfig.RootCAs == nil {
+		t.Fatal("expected RootCAs to be populated from ca-file")
+	}
+	if len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("expected exactly one client certificate, got %d", len(tlsConfig.Certificates))
+	}
+}
+
+// generateSelfSignedCert returns a PEM-encoded self-signed certificate and
+// its PEM-encoded private key, usable for both ca-file and
+// client-cert-file/client-key-file in tests.
+func generateSelfSignedCert(t *testing.T) (certPEM, keyPEM []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "http-cloudprovider-test"},
+		NotBefore:             time.Unix(0, 0),
+		NotAfter:              time.Unix(0, 0).Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM
+}
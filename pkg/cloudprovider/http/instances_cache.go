@@ -0,0 +1,186 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+
+	"github.com/golang/glog"
+)
+
+// instanceEntry is the cached view of a single instance, kept up to date by
+// watchInstances.
+type instanceEntry struct {
+	Name      string             `json:"name"`
+	Addresses []api.NodeAddress  `json:"addresses"`
+	Resources *api.NodeResources `json:"resources"`
+}
+
+// instanceEvent is a single line of the chunked watch response.
+type instanceEvent struct {
+	Type      string             `json:"type"`
+	Name      string             `json:"name"`
+	Addresses []api.NodeAddress  `json:"addresses"`
+	Resources *api.NodeResources `json:"resources"`
+}
+
+const (
+	instanceEventAdded    = "ADDED"
+	instanceEventModified = "MODIFIED"
+	instanceEventDeleted  = "DELETED"
+)
+
+// instanceCache is the RWMutex-protected map of instance name to
+// instanceEntry maintained from the watch stream.
+type instanceCache struct {
+	mu      sync.RWMutex
+	entries map[string]instanceEntry
+}
+
+func newInstanceCache() *instanceCache {
+	return &instanceCache{entries: make(map[string]instanceEntry)}
+}
+
+func (c *instanceCache) get(name string) (instanceEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[name]
+	return entry, ok
+}
+
+// list returns the names of the cached instances matching filter, or false
+// if the cache is empty or filter is not a valid regexp and the caller should
+// fall back to a direct GET.
+func (c *instanceCache) list(filter string) ([]string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.entries) == 0 {
+		return nil, false
+	}
+
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, false
+	}
+
+	names := make([]string, 0, len(c.entries))
+	for name := range c.entries {
+		if re.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names, true
+}
+
+func (c *instanceCache) apply(event instanceEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch event.Type {
+	case instanceEventAdded, instanceEventModified:
+		c.entries[event.Name] = instanceEntry{
+			Name:      event.Name,
+			Addresses: event.Addresses,
+			Resources: event.Resources,
+		}
+	case instanceEventDeleted:
+		delete(c.entries, event.Name)
+	default:
+		glog.Errorf("Ignoring instance watch event with unknown type %q for %s", event.Type, event.Name)
+	}
+}
+
+// watchInstances opens a long-lived streaming GET on the instances endpoint
+// and applies the ADDED/MODIFIED/DELETED events it receives to h.cache. It
+// reconnects with backoff on error and forces a resync by reconnecting every
+// h.resyncInterval, running until the process exits.
+func (h *httpCloud) watchInstances() {
+	attempt := 0
+	for {
+		resyncTimer := time.NewTimer(h.resyncInterval)
+		err := h.runWatch(resyncTimer.C)
+		resyncTimer.Stop()
+
+		if err != nil {
+			attempt++
+			backoff := backoffWithJitter(WatchReconnectBackoff, attempt)
+			glog.Errorf("Instance watch connection to %s failed, reconnecting in %v: %v", h.instancesURL, backoff, err)
+			time.Sleep(backoff)
+			continue
+		}
+
+		// Clean resync: reconnect immediately.
+		attempt = 0
+	}
+}
+
+// runWatch opens a single streaming connection and applies events to the
+// cache until the connection errors, EOFs, or resync fires. A nil return
+// means the resync timer fired on a healthy connection; any non-nil return
+// is a real connection/decode failure that should be retried with backoff.
+func (h *httpCloud) runWatch(resync <-chan time.Time) error {
+	req, err := http.NewRequest("GET", h.instancesURL+InstanceWatchPath, nil)
+	if err != nil {
+		return err
+	}
+	if h.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+h.bearerToken)
+	}
+	// The watch endpoint only ever emits chunked JSON events, regardless of
+	// h.codec, which governs the instances/scheduler-extension request and
+	// response bodies.
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.watchClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	events := make(chan instanceEvent)
+	decodeErrs := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var event instanceEvent
+			if err := decoder.Decode(&event); err != nil {
+				decodeErrs <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			h.cache.apply(event)
+		case err := <-decodeErrs:
+			return err
+		case <-resync:
+			return nil
+		}
+	}
+}
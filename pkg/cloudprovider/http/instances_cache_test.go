@@ -0,0 +1,116 @@
+/*
+Copyright 2014 The Kubernetes Authors All rights reserved.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/api"
+)
+
+func TestInstanceCacheGetMiss(t *testing.T) {
+	c := newInstanceCache()
+	if _, ok := c.get("node-1"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+}
+
+func TestInstanceCacheApplyAddedAndModified(t *testing.T) {
+	c := newInstanceCache()
+
+	c.apply(instanceEvent{
+		Type:      instanceEventAdded,
+		Name:      "node-1",
+		Addresses: []api.NodeAddress{{Address: "1.2.3.4"}},
+	})
+
+	entry, ok := c.get("node-1")
+	if !ok {
+		t.Fatal("expected node-1 to be present after an ADDED event")
+	}
+	if len(entry.Addresses) != 1 || entry.Addresses[0].Address != "1.2.3.4" {
+		t.Fatalf("entry.Addresses = %v, want [{... 1.2.3.4}]", entry.Addresses)
+	}
+
+	c.apply(instanceEvent{
+		Type:      instanceEventModified,
+		Name:      "node-1",
+		Addresses: []api.NodeAddress{{Address: "5.6.7.8"}},
+	})
+
+	entry, ok = c.get("node-1")
+	if !ok {
+		t.Fatal("expected node-1 to still be present after a MODIFIED event")
+	}
+	if len(entry.Addresses) != 1 || entry.Addresses[0].Address != "5.6.7.8" {
+		t.Fatalf("entry.Addresses after MODIFIED = %v, want [{... 5.6.7.8}]", entry.Addresses)
+	}
+}
+
+func TestInstanceCacheApplyDeleted(t *testing.T) {
+	c := newInstanceCache()
+	c.apply(instanceEvent{Type: instanceEventAdded, Name: "node-1"})
+	c.apply(instanceEvent{Type: instanceEventDeleted, Name: "node-1"})
+
+	if _, ok := c.get("node-1"); ok {
+		t.Fatal("expected node-1 to be gone after a DELETED event")
+	}
+}
+
+func TestInstanceCacheApplyUnknownEventType(t *testing.T) {
+	c := newInstanceCache()
+	c.apply(instanceEvent{Type: "BOGUS", Name: "node-1"})
+
+	if _, ok := c.get("node-1"); ok {
+		t.Fatal("expected an unknown event type to be ignored, not add an entry")
+	}
+}
+
+func TestInstanceCacheListEmpty(t *testing.T) {
+	c := newInstanceCache()
+	if names, ok := c.list(""); ok || names != nil {
+		t.Fatalf("list() on an empty cache = (%v, %v), want (nil, false)", names, ok)
+	}
+}
+
+func TestInstanceCacheListFiltersByRegexp(t *testing.T) {
+	c := newInstanceCache()
+	c.apply(instanceEvent{Type: instanceEventAdded, Name: "node-1"})
+	c.apply(instanceEvent{Type: instanceEventAdded, Name: "node-2"})
+	c.apply(instanceEvent{Type: instanceEventAdded, Name: "other"})
+
+	names, ok := c.list("node-.*")
+	if !ok {
+		t.Fatal("expected list to report ok on a non-empty cache")
+	}
+	if len(names) != 2 {
+		t.Fatalf("list(\"node-.*\") = %v, want 2 names", names)
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+	if !seen["node-1"] || !seen["node-2"] {
+		t.Fatalf("list(\"node-.*\") = %v, want [node-1 node-2] in some order", names)
+	}
+}
+
+func TestInstanceCacheListInvalidRegexp(t *testing.T) {
+	c := newInstanceCache()
+	c.apply(instanceEvent{Type: instanceEventAdded, Name: "node-1"})
+
+	if names, ok := c.list("("); ok || names != nil {
+		t.Fatalf("list() with an invalid regexp = (%v, %v), want (nil, false)", names, ok)
+	}
+}